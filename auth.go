@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authCacheTTL 是校验缓存条目的存活时间
+const authCacheTTL = 5 * time.Minute
+
+// authCacheMaxEntries 限制校验缓存的条目数上限。未认证的调用方可以用任意密码
+// 反复请求，每个不同密码都会产生一个新的 key，因此缓存必须有界，
+// 否则这个公网可达的鉴权端点会被人为撑爆内存
+const authCacheMaxEntries = 4096
+
+// authCacheEntry 是校验缓存中的一条记录，携带过期时间以便惰性淘汰
+type authCacheEntry struct {
+	valid   bool
+	expires time.Time
+}
+
+// basicAuthChecker 校验 HTTP Basic Auth 凭据，凭据哈希为 bcrypt。
+// 为避免每次请求都付出 bcrypt 的校验开销，校验结果按用户名 + 密码的
+// sha256 摘要缓存在内存中，缓存按 authCacheTTL 过期并受 authCacheMaxEntries 限制
+type basicAuthChecker struct {
+	users map[string]string // username -> bcrypt hash
+
+	mu    sync.Mutex
+	cache map[string]authCacheEntry // "user:sha256(password)" -> 上次校验结果
+}
+
+// newBasicAuthChecker 创建一个针对 users（username -> bcrypt hash）的校验器
+func newBasicAuthChecker(users map[string]string) *basicAuthChecker {
+	return &basicAuthChecker{
+		users: users,
+		cache: make(map[string]authCacheEntry),
+	}
+}
+
+// Verify 校验用户名密码是否匹配配置中的 bcrypt 哈希
+func (c *basicAuthChecker) Verify(username, password string) bool {
+	hash, ok := c.users[username]
+	if !ok {
+		return false
+	}
+
+	key := username + ":" + sha256Hex(password)
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, cached := c.cache[key]
+	c.mu.Unlock()
+	if cached && now.Before(entry.expires) {
+		return entry.valid
+	}
+
+	valid := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+
+	c.mu.Lock()
+	c.evictLocked(now)
+	c.cache[key] = authCacheEntry{valid: valid, expires: now.Add(authCacheTTL)}
+	c.mu.Unlock()
+
+	return valid
+}
+
+// evictLocked 清理已过期的缓存条目；调用方必须已持有 c.mu。
+// 如果清理过期条目后缓存仍达到 authCacheMaxEntries，说明短时间内涌入了
+// 大量不同的 key（例如密码喷洒），直接整体清空缓存而不是挨个淘汰，
+// 以保证内存占用始终有界
+func (c *basicAuthChecker) evictLocked(now time.Time) {
+	for key, entry := range c.cache {
+		if !now.Before(entry.expires) {
+			delete(c.cache, key)
+		}
+	}
+	if len(c.cache) >= authCacheMaxEntries {
+		c.cache = make(map[string]authCacheEntry)
+	}
+}
+
+// sha256Hex 返回 s 的 sha256 摘要的十六进制表示，仅用作缓存 key，
+// 避免在内存中以明文形式保留密码
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// basicAuthMiddleware 使用 bcrypt 哈希的用户表对请求做 Basic Auth 校验
+func basicAuthMiddleware(checker *basicAuthChecker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || !checker.Verify(user, pass) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ipAllowListMiddleware 仅放行源 IP 落在 cidrs 中任意一个网段的请求，
+// 在 basic-auth 校验之前执行
+func ipAllowListMiddleware(cidrs []*net.IPNet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !ipAllowed(ip, cidrs) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ipAllowed 判断 ip 是否落在 cidrs 中的任意一个网段
+func ipAllowed(ip net.IP, cidrs []*net.IPNet) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPRange 把配置中的 CIDR 字符串列表解析为 *net.IPNet 列表
+func parseIPRange(ranges []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(ranges))
+	for _, r := range ranges {
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// secureHandler 依次应用 IP allow-list（如果配置了）与 basic-auth 校验
+func secureHandler(webConfig *WebConfig, cidrs []*net.IPNet, next http.Handler) http.Handler {
+	handler := next
+	if len(webConfig.BasicAuthUsers) > 0 {
+		handler = basicAuthMiddleware(newBasicAuthChecker(webConfig.BasicAuthUsers), handler)
+	}
+	if len(cidrs) > 0 {
+		handler = ipAllowListMiddleware(cidrs, handler)
+	}
+	return handler
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIPAllowed(t *testing.T) {
+	_, cidr, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+	cidrs := []*net.IPNet{cidr}
+
+	if !ipAllowed(net.ParseIP("10.1.2.3"), cidrs) {
+		t.Fatalf("expected 10.1.2.3 to be allowed by 10.0.0.0/8")
+	}
+	if ipAllowed(net.ParseIP("192.168.1.1"), cidrs) {
+		t.Fatalf("expected 192.168.1.1 to be rejected")
+	}
+	if ipAllowed(net.ParseIP("10.1.2.3"), nil) {
+		t.Fatalf("expected no match against an empty cidr list")
+	}
+}
+
+func TestBasicAuthCheckerCachesResult(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	checker := newBasicAuthChecker(map[string]string{"alice": string(hash)})
+
+	if !checker.Verify("alice", "s3cret") {
+		t.Fatalf("expected correct password to verify")
+	}
+	if checker.Verify("alice", "wrong") {
+		t.Fatalf("expected incorrect password to fail")
+	}
+	if checker.Verify("bob", "s3cret") {
+		t.Fatalf("expected unknown username to fail")
+	}
+
+	if len(checker.cache) != 2 {
+		t.Fatalf("expected 2 cached entries, got %d", len(checker.cache))
+	}
+}
+
+func TestBasicAuthCheckerCacheIsBounded(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	checker := newBasicAuthChecker(map[string]string{"alice": string(hash)})
+
+	for i := 0; i < authCacheMaxEntries+10; i++ {
+		checker.Verify("alice", string(rune('a'+(i%26)))+string(rune(i)))
+	}
+
+	if len(checker.cache) >= authCacheMaxEntries+10 {
+		t.Fatalf("cache grew unbounded: %d entries", len(checker.cache))
+	}
+}
+
+func TestBasicAuthCheckerExpiresEntries(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	checker := newBasicAuthChecker(map[string]string{"alice": string(hash)})
+
+	checker.Verify("alice", "s3cret")
+
+	key := "alice:" + sha256Hex("s3cret")
+	checker.mu.Lock()
+	checker.cache[key] = authCacheEntry{valid: true, expires: time.Now().Add(-time.Second)}
+	checker.evictLocked(time.Now())
+	_, stillCached := checker.cache[key]
+	checker.mu.Unlock()
+
+	if stillCached {
+		t.Fatalf("expected expired entry to be evicted")
+	}
+}
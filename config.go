@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// URLConfig 定义配置文件结构
+type URLConfig struct {
+	URLs      []URLItem         `json:"urls" yaml:"urls"`
+	Settings  Settings          `json:"settings" yaml:"settings"`
+	Modules   map[string]Module `json:"modules" yaml:"modules"`
+	Push      *PushConfig       `json:"push" yaml:"push"`
+	Discovery *DiscoveryConfig  `json:"discovery" yaml:"discovery"`
+}
+
+// URLItem 定义单个探测目标的结构。Type 选择使用哪种 Prober（默认 "http"），
+// 对应类型的参数块（TCP/ICMP/DNS/GRPC）仅在该类型下生效
+type URLItem struct {
+	URL  string `json:"url" yaml:"url"`
+	IP   string `json:"ip" yaml:"ip"`
+	Type string `json:"type" yaml:"type"`
+
+	TCP  *TCPParams  `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	ICMP *ICMPParams `json:"icmp,omitempty" yaml:"icmp,omitempty"`
+	DNS  *DNSParams  `json:"dns,omitempty" yaml:"dns,omitempty"`
+	GRPC *GRPCParams `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+
+	// Labels 携带服务发现（file_sd/consul_sd）relabel 之后保留下来的额外标签，
+	// 静态配置中的条目一般不需要填写
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Settings 定义全局设置的结构
+type Settings struct {
+	UpdateFreq              int       `json:"update_freq" yaml:"update_freq"`
+	Timeout                 int       `json:"timeout" yaml:"timeout"`
+	Concurrency             int       `json:"concurrency" yaml:"concurrency"`
+	DurationBuckets         []float64 `json:"duration_buckets" yaml:"duration_buckets"`
+	DisableScheduledProbing bool      `json:"disable_scheduled_probing" yaml:"disable_scheduled_probing"`
+}
+
+// defaultConcurrency 是未在配置中指定 concurrency 时使用的并发探测数
+const defaultConcurrency = 5
+
+// decodeFile 按文件扩展名将 path 解码到 out：.yaml/.yml 使用 YAML，其余按 JSON 处理
+func decodeFile(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, out)
+	default:
+		return json.Unmarshal(data, out)
+	}
+}
+
+// loadConfig 读取并校验 URL 配置文件，支持 JSON 或 YAML 格式
+func loadConfig(path string) (*URLConfig, error) {
+	var config URLConfig
+	if err := decodeFile(path, &config); err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// validateConfig 校验配置的内部一致性：探测目标与 module 的 prober 都必须
+// 是已知协议，二者中出现的所有正则表达式都必须能够编译成功
+func validateConfig(config *URLConfig) error {
+	for _, item := range config.URLs {
+		if _, err := proberFor(item.Type); err != nil {
+			return fmt.Errorf("url %q：%w", item.URL, err)
+		}
+		if err := validateProtocolParams(item.TCP, item.DNS); err != nil {
+			return fmt.Errorf("url %q 的%w", item.URL, err)
+		}
+	}
+
+	for name, module := range config.Modules {
+		if _, err := proberFor(moduleProberType(module)); err != nil {
+			return fmt.Errorf("module %q：%w", name, err)
+		}
+		if err := validateProtocolParams(module.TCP, module.DNS); err != nil {
+			return fmt.Errorf("module %q 的%w", name, err)
+		}
+		if moduleProberType(module) == "http" {
+			if _, err := buildProbeOptions(module); err != nil {
+				return fmt.Errorf("module %q 非法：%w", name, err)
+			}
+		}
+	}
+
+	if _, err := buildDiscoverers(config.Discovery); err != nil {
+		return fmt.Errorf("discovery 配置非法：%w", err)
+	}
+
+	return nil
+}
+
+// validateProtocolParams 校验 TCP/DNS 参数块中出现的正则表达式，
+// URLItem 与 Module 共用同一套 TCPParams/DNSParams 结构
+func validateProtocolParams(tcp *TCPParams, dns *DNSParams) error {
+	if tcp != nil {
+		if err := compileIfSet(tcp.BannerRegexp); err != nil {
+			return fmt.Errorf("tcp.banner_regexp 非法：%w", err)
+		}
+	}
+	if dns != nil {
+		if err := compileIfSet(dns.AnswerRegexp); err != nil {
+			return fmt.Errorf("dns.answer_regexp 非法：%w", err)
+		}
+	}
+	return nil
+}
+
+// compileIfSet 在 pattern 非空时校验其可以被编译为正则表达式
+func compileIfSet(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	_, err := regexp.Compile(pattern)
+	return err
+}
+
+// ConfigStore 持有当前生效的配置，支持并发读取与原子热替换
+type ConfigStore struct {
+	path string
+	ptr  atomic.Pointer[URLConfig]
+}
+
+// NewConfigStore 加载 path 指向的配置文件并返回一个可热重载的 ConfigStore
+func NewConfigStore(path string) (*ConfigStore, error) {
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &ConfigStore{path: path}
+	cs.ptr.Store(config)
+	return cs, nil
+}
+
+// Load 返回当前生效的配置
+func (cs *ConfigStore) Load() *URLConfig {
+	return cs.ptr.Load()
+}
+
+// Reload 重新读取并校验配置文件，校验通过后原子替换当前配置；
+// 校验失败时保留旧配置不变并返回错误
+func (cs *ConfigStore) Reload() error {
+	config, err := loadConfig(cs.path)
+	if err != nil {
+		return err
+	}
+
+	cs.ptr.Store(config)
+	return nil
+}
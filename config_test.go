@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const validReloadConfig = `{
+	"urls": [{"url": "http://example.com", "ip": "1.2.3.4"}],
+	"settings": {"update_freq": 10, "timeout": 5, "concurrency": 1}
+}`
+
+const invalidReloadConfig = `{
+	"urls": [{"url": "http://example.com", "type": "no-such-prober"}],
+	"settings": {"update_freq": 10, "timeout": 5, "concurrency": 1}
+}`
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "url.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestConfigStoreReloadSwapsOnSuccess(t *testing.T) {
+	path := writeConfigFile(t, validReloadConfig)
+	cs, err := NewConfigStore(path)
+	if err != nil {
+		t.Fatalf("NewConfigStore: %v", err)
+	}
+
+	if len(cs.Load().URLs) != 1 {
+		t.Fatalf("expected 1 initial url, got %d", len(cs.Load().URLs))
+	}
+
+	if err := os.WriteFile(path, []byte(`{
+		"urls": [],
+		"settings": {"update_freq": 10, "timeout": 5, "concurrency": 1}
+	}`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := cs.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if len(cs.Load().URLs) != 0 {
+		t.Fatalf("expected reload to swap in the new config with 0 urls, got %d", len(cs.Load().URLs))
+	}
+}
+
+func TestConfigStoreReloadKeepsOldConfigOnError(t *testing.T) {
+	path := writeConfigFile(t, validReloadConfig)
+	cs, err := NewConfigStore(path)
+	if err != nil {
+		t.Fatalf("NewConfigStore: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(invalidReloadConfig), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := cs.Reload(); err == nil {
+		t.Fatalf("expected Reload to fail on an invalid config")
+	}
+
+	if len(cs.Load().URLs) != 1 {
+		t.Fatalf("expected old config to be kept after a failed reload, got %d urls", len(cs.Load().URLs))
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulSDConfig 配置基于 Consul catalog 的服务发现：对 Services 中列出的
+// 每个服务名分别发起阻塞查询（blocking query），仅返回健康的实例，
+// Tag 非空时只保留带有该 tag 的实例
+type ConsulSDConfig struct {
+	Address         string   `json:"address" yaml:"address"`
+	Services        []string `json:"services" yaml:"services"`
+	Tag             string   `json:"tag" yaml:"tag"`
+	Scheme          string   `json:"scheme" yaml:"scheme"`
+	RefreshInterval int      `json:"refresh_interval" yaml:"refresh_interval"`
+}
+
+// defaultConsulWaitSeconds 是阻塞查询单次等待的默认超时时间
+const defaultConsulWaitSeconds = 30
+
+// ConsulDiscoverer 对配置中的每个 Consul 服务名维护一条独立的阻塞查询，
+// 服务实例列表发生变化（由 Consul catalog index 驱动）时重新上报目标
+type ConsulDiscoverer struct {
+	cfg ConsulSDConfig
+}
+
+// NewConsulDiscoverer 创建一个基于 cfg 的 ConsulDiscoverer
+func NewConsulDiscoverer(cfg ConsulSDConfig) (*ConsulDiscoverer, error) {
+	return &ConsulDiscoverer{cfg: cfg}, nil
+}
+
+// Run 实现 Discoverer：为每个配置的服务名启动一个独立的阻塞查询循环
+func (d *ConsulDiscoverer) Run(ctx context.Context, ch chan<- *TargetGroup) {
+	client, err := api.NewClient(&api.Config{Address: d.cfg.Address})
+	if err != nil {
+		log.Printf("consul_sd：创建 Consul 客户端失败：%v", err)
+		return
+	}
+
+	wait := time.Duration(d.cfg.RefreshInterval) * time.Second
+	if wait <= 0 {
+		wait = defaultConsulWaitSeconds * time.Second
+	}
+
+	var wg sync.WaitGroup
+	for _, service := range d.cfg.Services {
+		wg.Add(1)
+		go func(service string) {
+			defer wg.Done()
+			d.watchService(ctx, client, service, wait, ch)
+		}(service)
+	}
+	wg.Wait()
+}
+
+// watchService 对单个服务名持续发起阻塞查询，每次 catalog index 变化都
+// 重新构建该服务的目标集合并上报
+func (d *ConsulDiscoverer) watchService(ctx context.Context, client *api.Client, service string, wait time.Duration, ch chan<- *TargetGroup) {
+	var lastIndex uint64
+	source := "consul_sd:" + service
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		entries, meta, err := client.Health().Service(service, d.cfg.Tag, true, &api.QueryOptions{
+			WaitIndex: lastIndex,
+			WaitTime:  wait,
+		})
+		if err != nil {
+			log.Printf("consul_sd：查询服务 %s 失败：%v", service, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		lastIndex = meta.LastIndex
+
+		targets := make([]map[string]string, 0, len(entries))
+		for _, entry := range entries {
+			targets = append(targets, consulEntryLabels(entry, d.cfg.Scheme))
+		}
+
+		select {
+		case ch <- &TargetGroup{Source: source, Targets: targets}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// consulEntryLabels 把一个健康的 Consul 服务实例转换为发现 label 集合，
+// __meta_consul_* 与 Prometheus consul_sd 的命名保持一致
+func consulEntryLabels(entry *api.ServiceEntry, scheme string) map[string]string {
+	address := entry.Service.Address
+	if address == "" {
+		address = entry.Node.Address
+	}
+
+	tags := ""
+	if len(entry.Service.Tags) > 0 {
+		tags = "," + strings.Join(entry.Service.Tags, ",") + ","
+	}
+
+	labels := map[string]string{
+		"__meta_consul_node":         entry.Node.Node,
+		"__meta_consul_address":      address,
+		"__meta_consul_service":      entry.Service.Service,
+		"__meta_consul_service_id":   entry.Service.ID,
+		"__meta_consul_service_port": strconv.Itoa(entry.Service.Port),
+		"__meta_consul_tags":         tags,
+		reservedLabelAddress:         address + ":" + strconv.Itoa(entry.Service.Port),
+	}
+	if scheme != "" {
+		labels[reservedLabelScheme] = scheme
+	}
+
+	return labels
+}
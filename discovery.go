@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// reservedLabel 是探测目标元数据中用来生成 URLItem 字段的保留 label 名
+const (
+	reservedLabelAddress = "__address__" // host:port，file_sd/consul_sd 的发现结果都以此为准
+	reservedLabelScheme  = "__scheme__"  // 默认 http
+	reservedLabelPath    = "__path__"    // 拼接到 URL 末尾的路径
+	reservedLabelType    = "type"        // 对应 URLItem.Type，默认 http
+	reservedLabelURL     = "url"         // relabel 之后可直接指定最终 URL，优先于 __address__ 拼接
+	reservedLabelIP      = "ip"          // relabel 之后可直接指定 URLItem.IP
+)
+
+// DiscoveryConfig 配置服务发现子系统：file_sd 与 consul_sd 可以同时启用，
+// RelabelConfigs 按顺序应用到全部发现源产出的目标上，ExtraLabels 列出的
+// label 名会在 relabel 之后保留到 URLItem.Labels，从而成为 probe_* 指标的额外标签
+type DiscoveryConfig struct {
+	FileSD         []FileSDConfig  `json:"file_sd" yaml:"file_sd"`
+	ConsulSD       *ConsulSDConfig `json:"consul_sd" yaml:"consul_sd"`
+	RelabelConfigs []RelabelConfig `json:"relabel_configs" yaml:"relabel_configs"`
+	ExtraLabels    []string        `json:"extra_labels" yaml:"extra_labels"`
+}
+
+// TargetGroup 是一个发现源在某一时刻产出的目标集合，Source 用于在合并多个
+// 发现源的结果时区分归属，便于后续增量更新时按来源替换
+type TargetGroup struct {
+	Source  string
+	Targets []map[string]string
+}
+
+// Discoverer 持续发现探测目标并通过 ch 上报，直到 ctx 被取消。
+// 每次发现结果变化时都应把该来源完整的目标集合重新发送一次
+type Discoverer interface {
+	Run(ctx context.Context, ch chan<- *TargetGroup)
+}
+
+// buildDiscoverers 根据 DiscoveryConfig 构建配置中启用的全部 Discoverer
+func buildDiscoverers(cfg *DiscoveryConfig) ([]Discoverer, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var discoverers []Discoverer
+	for _, fileCfg := range cfg.FileSD {
+		discoverers = append(discoverers, NewFileDiscoverer(fileCfg))
+	}
+
+	if cfg.ConsulSD != nil {
+		consulDiscoverer, err := NewConsulDiscoverer(*cfg.ConsulSD)
+		if err != nil {
+			return nil, err
+		}
+		discoverers = append(discoverers, consulDiscoverer)
+	}
+
+	return discoverers, nil
+}
+
+// mergeDiscoverers 启动 discoverers 并将其产出合并为一个 URLItem 的
+// 合并结果流：每当任意一个来源更新，都会用该来源的最新结果替换旧值，
+// 对全部来源重新应用 relabel 配置后，把合并后的完整目标列表发送到返回的 channel
+func mergeDiscoverers(ctx context.Context, discoverers []Discoverer, relabelConfigs []RelabelConfig, extraLabels []string) <-chan []URLItem {
+	out := make(chan []URLItem)
+	groups := make(chan *TargetGroup)
+
+	for _, d := range discoverers {
+		go d.Run(ctx, groups)
+	}
+
+	go func() {
+		defer close(out)
+
+		bySource := make(map[string][]map[string]string)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case group := <-groups:
+				bySource[group.Source] = group.Targets
+
+				var merged []map[string]string
+				for _, targets := range bySource {
+					merged = append(merged, targets...)
+				}
+
+				items := relabelTargets(merged, relabelConfigs, extraLabels)
+				select {
+				case out <- items:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// TargetStore 持有服务发现当前产出的目标集合，支持并发读取与原子替换，
+// 风格上与 ConfigStore 一致
+type TargetStore struct {
+	ptr atomic.Pointer[[]URLItem]
+}
+
+// NewTargetStore 创建一个初始为空目标集合的 TargetStore
+func NewTargetStore() *TargetStore {
+	ts := &TargetStore{}
+	empty := []URLItem{}
+	ts.ptr.Store(&empty)
+	return ts
+}
+
+// Load 返回当前服务发现产出的目标集合
+func (ts *TargetStore) Load() []URLItem {
+	return *ts.ptr.Load()
+}
+
+// DiscoveryManager 负责根据 DiscoveryConfig 启停服务发现子系统，并允许在
+// 配置热重载时重建 Discoverer（/-/reload、SIGHUP），而不是只能在进程启动时
+// 构建一次。TargetStore 本身在整个生命周期内保持不变，Reload 只替换其
+// 背后正在运行的 Discoverer 集合
+type DiscoveryManager struct {
+	store *TargetStore
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewDiscoveryManager 创建一个尚未启动任何 Discoverer 的 DiscoveryManager，
+// 调用方需要紧接着调用一次 Reload 来启动初始配置
+func NewDiscoveryManager() *DiscoveryManager {
+	return &DiscoveryManager{store: NewTargetStore()}
+}
+
+// Store 返回该 DiscoveryManager 持有的 TargetStore，其内容会随每次 Reload 更新
+func (dm *DiscoveryManager) Store() *TargetStore {
+	return dm.store
+}
+
+// Reload 根据 discovery 重新构建全部 Discoverer：先尝试构建新的 Discoverer
+// （例如校验 consul_sd 的客户端配置），构建失败时保留旧的 Discoverer 继续运行
+// 并返回错误；构建成功后才停止旧的 Discoverer、启动新的，因此调用期间
+// TargetStore 不会出现目标短暂清空的窗口
+func (dm *DiscoveryManager) Reload(discovery *DiscoveryConfig) error {
+	discoverers, err := buildDiscoverers(discovery)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dm.mu.Lock()
+	oldCancel := dm.cancel
+	dm.cancel = cancel
+	dm.mu.Unlock()
+	if oldCancel != nil {
+		oldCancel()
+	}
+
+	if len(discoverers) == 0 {
+		empty := []URLItem{}
+		dm.store.ptr.Store(&empty)
+		return nil
+	}
+
+	relabelConfigs := discovery.RelabelConfigs
+	extraLabels := discovery.ExtraLabels
+	merged := mergeDiscoverers(ctx, discoverers, relabelConfigs, extraLabels)
+	go func() {
+		for targets := range merged {
+			targets := targets
+			dm.store.ptr.Store(&targets)
+		}
+	}()
+
+	return nil
+}
+
+// Stop 停止当前正在运行的全部 Discoverer，用于进程退出时释放资源
+func (dm *DiscoveryManager) Stop() {
+	dm.mu.Lock()
+	cancel := dm.cancel
+	dm.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// relabelTargets 把发现产出的原始 label 集合依次应用 relabel 配置，
+// 并把最终保留的每一个目标转换为 URLItem；被 relabel 规则丢弃的目标不会出现在结果中
+func relabelTargets(targets []map[string]string, relabelConfigs []RelabelConfig, extraLabels []string) []URLItem {
+	items := make([]URLItem, 0, len(targets))
+	for _, target := range targets {
+		labels, keep := Relabel(target, relabelConfigs)
+		if !keep {
+			continue
+		}
+
+		items = append(items, targetToURLItem(labels, extraLabels))
+	}
+	return items
+}
+
+// targetToURLItem 把 relabel 后的最终 label 集合转换为一个 URLItem：
+// url 优先取 reservedLabelURL，否则由 __address__ + __scheme__ + __path__ 拼接而成；
+// extraLabels 中列出的 label 会被保留到 URLItem.Labels，成为指标的额外标签
+func targetToURLItem(labels map[string]string, extraLabels []string) URLItem {
+	url := labels[reservedLabelURL]
+	if url == "" {
+		scheme := labels[reservedLabelScheme]
+		if scheme == "" {
+			scheme = "http"
+		}
+		url = scheme + "://" + labels[reservedLabelAddress] + labels[reservedLabelPath]
+	}
+
+	ip := labels[reservedLabelIP]
+	if ip == "" {
+		ip = labels[reservedLabelAddress]
+	}
+
+	probeType := labels[reservedLabelType]
+
+	item := URLItem{URL: url, IP: ip, Type: probeType}
+	if len(extraLabels) > 0 {
+		item.Labels = make(map[string]string, len(extraLabels))
+		for _, name := range extraLabels {
+			item.Labels[name] = labels[name]
+		}
+	}
+	return item
+}
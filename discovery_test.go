@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeDiscoverer 只发出一组固定的目标，然后一直阻塞直到 ctx 被取消，
+// 用于在不依赖文件系统/Consul 的情况下测试 mergeDiscoverers 的合并逻辑
+type fakeDiscoverer struct {
+	group *TargetGroup
+}
+
+func (f *fakeDiscoverer) Run(ctx context.Context, ch chan<- *TargetGroup) {
+	select {
+	case ch <- f.group:
+	case <-ctx.Done():
+		return
+	}
+	<-ctx.Done()
+}
+
+// TestMergeDiscoverersKeepsDistinctSources 回归测试：两个来源（例如两个
+// file_sd 块）各自上报一个目标时，合并结果必须包含两者，而不是后一个
+// 覆盖前一个——这正是此前 FileDiscoverer 都使用固定 Source "file_sd" 时
+// 触发的 bug
+func TestMergeDiscoverersKeepsDistinctSources(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	discoverers := []Discoverer{
+		&fakeDiscoverer{group: &TargetGroup{
+			Source:  "file_sd:a.json",
+			Targets: []map[string]string{{"__address__": "10.0.0.1:80"}},
+		}},
+		&fakeDiscoverer{group: &TargetGroup{
+			Source:  "file_sd:b.json",
+			Targets: []map[string]string{{"__address__": "10.0.0.2:80"}},
+		}},
+	}
+
+	out := mergeDiscoverers(ctx, discoverers, nil, nil)
+
+	var last []URLItem
+	for {
+		select {
+		case items, ok := <-out:
+			if !ok {
+				if len(last) != 2 {
+					t.Fatalf("expected 2 merged targets from distinct sources, got %d: %+v", len(last), last)
+				}
+				return
+			}
+			last = items
+			if len(last) == 2 {
+				cancel()
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for merged targets, last seen: %+v", last)
+		}
+	}
+}
+
+// waitForTargets 轮询 store 直到其中的目标数达到 want 或超时，
+// 用于等待 FileDiscoverer 异步完成首次 sync
+func waitForTargets(t *testing.T, store *TargetStore, want int) []URLItem {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if targets := store.Load(); len(targets) == want {
+			return targets
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return store.Load()
+}
+
+// TestDiscoveryManagerReloadRebuildsDiscoverers 回归测试：chunk0-7 引入的
+// file_sd/consul_sd 最初只在进程启动时构建一次，/-/reload、SIGHUP 都无法让
+// discovery.* 的变更生效。这里验证 DiscoveryManager.Reload 在运行期间
+// 重新配置 file_sd 之后，TargetStore 会反映新的发现结果
+func TestDiscoveryManagerReloadRebuildsDiscoverers(t *testing.T) {
+	dm := NewDiscoveryManager()
+	if err := dm.Reload(nil); err != nil {
+		t.Fatalf("initial Reload: %v", err)
+	}
+	if len(dm.Store().Load()) != 0 {
+		t.Fatalf("expected empty target store with no discovery config")
+	}
+
+	dir := t.TempDir()
+	sdFile := filepath.Join(dir, "targets.json")
+	if err := os.WriteFile(sdFile, []byte(`[{"targets": ["127.0.0.1:9100"]}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	discovery := &DiscoveryConfig{FileSD: []FileSDConfig{{Files: []string{sdFile}}}}
+	if err := dm.Reload(discovery); err != nil {
+		t.Fatalf("Reload with file_sd: %v", err)
+	}
+
+	targets := waitForTargets(t, dm.Store(), 1)
+	if len(targets) != 1 {
+		t.Fatalf("expected 1 discovered target, got %d", len(targets))
+	}
+}
+
+// TestReloadConfigRebuildsDiscoveryOnSuccess 验证 reloadConfig 在配置文件
+// 重载成功后，会用新配置中的 discovery.* 重建 discoveryManager，
+// 而不是让服务发现配置停留在进程启动时的状态
+func TestReloadConfigRebuildsDiscoveryOnSuccess(t *testing.T) {
+	path := writeConfigFile(t, validReloadConfig)
+	cs, err := NewConfigStore(path)
+	if err != nil {
+		t.Fatalf("NewConfigStore: %v", err)
+	}
+
+	dm := NewDiscoveryManager()
+	if err := dm.Reload(cs.Load().Discovery); err != nil {
+		t.Fatalf("initial discovery Reload: %v", err)
+	}
+	reloadMetrics := NewReloadMetrics()
+
+	if err := reloadConfig(cs, dm, reloadMetrics, "test"); err != nil {
+		t.Fatalf("reloadConfig: %v", err)
+	}
+	if got := testutil.ToFloat64(reloadMetrics.LastReloadSuccessful); got != 1 {
+		t.Fatalf("LastReloadSuccessful = %v, want 1", got)
+	}
+}
+
+// TestReloadConfigKeepsOldConfigAndRecordsFailure 验证配置文件本身校验失败时，
+// reloadConfig 保留旧配置、不触碰 discoveryManager，并把失败记录到 reloadMetrics
+func TestReloadConfigKeepsOldConfigAndRecordsFailure(t *testing.T) {
+	path := writeConfigFile(t, validReloadConfig)
+	cs, err := NewConfigStore(path)
+	if err != nil {
+		t.Fatalf("NewConfigStore: %v", err)
+	}
+
+	dm := NewDiscoveryManager()
+	if err := dm.Reload(cs.Load().Discovery); err != nil {
+		t.Fatalf("initial discovery Reload: %v", err)
+	}
+	reloadMetrics := NewReloadMetrics()
+	reloadMetrics.RecordSuccess()
+
+	if err := os.WriteFile(path, []byte(invalidReloadConfig), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := reloadConfig(cs, dm, reloadMetrics, "test"); err == nil {
+		t.Fatalf("expected reloadConfig to fail on an invalid config")
+	}
+
+	if len(cs.Load().URLs) != 1 {
+		t.Fatalf("expected old config to be kept after a failed reload, got %d urls", len(cs.Load().URLs))
+	}
+	if got := testutil.ToFloat64(reloadMetrics.LastReloadSuccessful); got != 0 {
+		t.Fatalf("LastReloadSuccessful = %v, want 0 after a failed reload", got)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSParams 是 type 为 "dns" 的 URLItem 的参数块。URLItem.URL 被当作
+// "host:port" 的解析器地址使用
+type DNSParams struct {
+	QueryName    string   `json:"query_name"`
+	QueryType    string   `json:"query_type"`
+	ValidRcodes  []string `json:"valid_rcodes"`
+	AnswerRegexp string   `json:"answer_regexp"`
+}
+
+// defaultDNSQueryType 在 DNSParams 未指定 query_type 时使用
+const defaultDNSQueryType = "A"
+
+// dnsQueryTypes 把配置中的字符串映射到 dnsmessage 的记录类型
+var dnsQueryTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+// DNSProber 实现了 Prober 接口：向配置的解析器发起一次 DNS 查询，
+// 校验返回的 rcode 并可选匹配应答记录的正则表达式
+type DNSProber struct{}
+
+// Probe 实现 Prober 接口
+func (p *DNSProber) Probe(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	result := ProbeResult{Durations: make(map[string]float64)}
+
+	params := DNSParams{}
+	if item.DNS != nil {
+		params = *item.DNS
+	}
+	if params.QueryName == "" {
+		return result, fmt.Errorf("dns 探测缺少 query_name")
+	}
+
+	queryTypeName := params.QueryType
+	if queryTypeName == "" {
+		queryTypeName = defaultDNSQueryType
+	}
+	queryType, ok := dnsQueryTypes[strings.ToUpper(queryTypeName)]
+	if !ok {
+		return result, fmt.Errorf("不支持的 query_type：%q", queryTypeName)
+	}
+
+	name, err := dnsmessage.NewName(dotSuffixed(params.QueryName))
+	if err != nil {
+		return result, fmt.Errorf("非法的 query_name：%w", err)
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  queryType,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return result, err
+	}
+
+	conn, err := net.DialTimeout("udp", item.URL, timeout)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return result, err
+	}
+	if _, err := conn.Write(packed); err != nil {
+		return result, err
+	}
+
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return result, err
+	}
+	result.Durations[phaseConnect] = time.Since(start).Seconds()
+
+	var response dnsmessage.Message
+	if err := response.Unpack(buf[:n]); err != nil {
+		return result, err
+	}
+
+	result.HasDNSInfo = true
+	result.DNSAnswerRRs = len(response.Answers)
+	result.Success = rcodeMatches(response.Header.RCode, params.ValidRcodes) &&
+		answersMatch(response.Answers, params.AnswerRegexp)
+
+	return result, nil
+}
+
+// dotSuffixed 确保域名以 "." 结尾，这是 dnsmessage.NewName 所要求的格式
+func dotSuffixed(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// rcodeMatches 判断响应码是否满足要求：valid 为空时要求 NOERROR
+func rcodeMatches(rcode dnsmessage.RCode, valid []string) bool {
+	if len(valid) == 0 {
+		return rcode == dnsmessage.RCodeSuccess
+	}
+	for _, name := range valid {
+		if strings.EqualFold(name, rcode.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// answersMatch 判断应答记录中是否有任意一条匹配给定正则；pattern 为空时视为通过
+func answersMatch(answers []dnsmessage.Resource, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	for _, answer := range answers {
+		if re.MatchString(answer.Body.GoString()) {
+			return true
+		}
+	}
+	return false
+}
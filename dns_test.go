@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestRcodeMatches(t *testing.T) {
+	if !rcodeMatches(dnsmessage.RCodeSuccess, nil) {
+		t.Fatalf("empty valid list should default to requiring NOERROR")
+	}
+	if rcodeMatches(dnsmessage.RCodeNameError, nil) {
+		t.Fatalf("NXDOMAIN should not satisfy the default NOERROR requirement")
+	}
+	if !rcodeMatches(dnsmessage.RCodeNameError, []string{"RCodeNameError"}) {
+		t.Fatalf("explicit valid_rcodes should accept a matching rcode name")
+	}
+	if !rcodeMatches(dnsmessage.RCodeNameError, []string{"rcodenameerror"}) {
+		t.Fatalf("rcode names should be matched case-insensitively")
+	}
+}
+
+func TestAnswersMatchEmptyPattern(t *testing.T) {
+	if !answersMatch(nil, "") {
+		t.Fatalf("empty pattern should always match, even with no answers")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileSDConfig 对应 Prometheus file_sd_config 风格的文件服务发现：Files 是
+// 一组文件路径或 glob（如 "targets/*.json"），每个文件内容是一组 target group：
+// [{"targets": ["host:port", ...], "labels": {"env": "prod"}}, ...]
+type FileSDConfig struct {
+	Files []string `json:"files" yaml:"files"`
+}
+
+// fileTargetGroup 对应 file_sd 文件里的单个 target group
+type fileTargetGroup struct {
+	Targets []string          `json:"targets" yaml:"targets"`
+	Labels  map[string]string `json:"labels" yaml:"labels"`
+}
+
+// FileDiscoverer 监视一组文件（或 glob），变化时重新读取并上报合并后的目标
+type FileDiscoverer struct {
+	patterns []string
+	source   string
+}
+
+// NewFileDiscoverer 创建一个基于 cfg.Files 的 FileDiscoverer。source 由
+// Files 拼接而成，确保配置了多个 file_sd 块时各自的 TargetGroup.Source
+// 互不相同，避免在 mergeDiscoverers 中按 Source 合并时互相覆盖
+func NewFileDiscoverer(cfg FileSDConfig) *FileDiscoverer {
+	return &FileDiscoverer{
+		patterns: cfg.Files,
+		source:   "file_sd:" + strings.Join(cfg.Files, ","),
+	}
+}
+
+// Run 实现 Discoverer：启动时先读取一次匹配到的全部文件，随后通过 fsnotify
+// 监听这些文件所在目录，任意相关文件发生变化都会触发重新读取
+func (d *FileDiscoverer) Run(ctx context.Context, ch chan<- *TargetGroup) {
+	d.sync(ctx, ch)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("file_sd：创建文件监视器失败：%v", err)
+		return
+	}
+	defer watcher.Close()
+
+	dirs := make(map[string]struct{})
+	for _, pattern := range d.patterns {
+		dirs[filepath.Dir(pattern)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			log.Printf("file_sd：监视目录 %s 失败：%v", dir, err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watcher.Errors:
+			log.Printf("file_sd：监视器报错：%v", err)
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			d.sync(ctx, ch)
+		}
+	}
+}
+
+// sync 读取所有匹配 patterns 的文件并上报合并后的目标集合
+func (d *FileDiscoverer) sync(ctx context.Context, ch chan<- *TargetGroup) {
+	var targets []map[string]string
+
+	for _, pattern := range d.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Printf("file_sd：解析 glob %q 失败：%v", pattern, err)
+			continue
+		}
+
+		for _, path := range matches {
+			var groups []fileTargetGroup
+			if err := decodeFile(path, &groups); err != nil {
+				log.Printf("file_sd：读取 %s 失败：%v", path, err)
+				continue
+			}
+
+			for _, group := range groups {
+				for _, address := range group.Targets {
+					labels := make(map[string]string, len(group.Labels)+1)
+					for k, v := range group.Labels {
+						labels[k] = v
+					}
+					labels[reservedLabelAddress] = address
+					targets = append(targets, labels)
+				}
+			}
+		}
+	}
+
+	select {
+	case ch <- &TargetGroup{Source: d.source, Targets: targets}:
+	case <-ctx.Done():
+	}
+}
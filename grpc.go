@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCParams 是 type 为 "grpc" 的 URLItem 的参数块
+type GRPCParams struct {
+	Service            string `json:"service"`
+	TLS                bool   `json:"tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// GRPCProber 实现了 Prober 接口：调用 grpc.health.v1.Health/Check 并
+// 根据返回的 serving status 判定探测是否成功
+type GRPCProber struct{}
+
+// Probe 实现 Prober 接口
+func (p *GRPCProber) Probe(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	result := ProbeResult{Durations: make(map[string]float64)}
+
+	params := GRPCParams{}
+	if item.GRPC != nil {
+		params = *item.GRPC
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	transportCreds := insecure.NewCredentials()
+	if params.TLS {
+		transportCreds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: params.InsecureSkipVerify})
+	}
+
+	start := time.Now()
+	conn, err := grpc.DialContext(ctx, item.URL,
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+	result.Durations[phaseConnect] = time.Since(start).Seconds()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: params.Service})
+	if err != nil {
+		return result, err
+	}
+
+	result.Success = resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+
+	return result, nil
+}
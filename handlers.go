@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// defaultModuleName 在请求未携带 module 参数时使用
+const defaultModuleName = "http_2xx"
+
+// probeHandler 实现 blackbox_exporter 风格的按需探测端点：
+// GET /probe?target=<url>&module=<name>。每次请求都会从 configStore 读取
+// 最新生效的 module 配置，因此配置热重载对 /probe 立即生效
+func probeHandler(configStore *ConfigStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "缺少 target 参数", http.StatusBadRequest)
+			return
+		}
+
+		moduleName := r.URL.Query().Get("module")
+		if moduleName == "" {
+			moduleName = defaultModuleName
+		}
+
+		config := configStore.Load()
+		module, ok := config.Modules[moduleName]
+		if !ok {
+			http.Error(w, fmt.Sprintf("未知的 module：%q", moduleName), http.StatusBadRequest)
+			return
+		}
+
+		timeout := moduleTimeout(module)
+
+		var result ProbeResult
+		var err error
+		if moduleProberType(module) == "http" {
+			var opts ProbeOptions
+			opts, err = buildProbeOptions(module)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			result, err = (&HTTPProber{}).ProbeWithOptions(target, timeout, opts)
+		} else {
+			prober, proberErr := proberFor(moduleProberType(module))
+			if proberErr != nil {
+				http.Error(w, proberErr.Error(), http.StatusBadRequest)
+				return
+			}
+			result, err = prober.Probe(moduleTargetItem(module, target), timeout)
+		}
+
+		metrics := NewAdHocMetrics(config.Settings.DurationBuckets)
+		registry := prometheus.NewRegistry()
+		metrics.Register(registry)
+
+		if err != nil {
+			log.Printf("按需探测 %s（module=%s）失败：%v", target, moduleName, err)
+			metrics.Success.Set(0)
+		} else {
+			metrics.Observe(result)
+		}
+
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	}
+}
+
+// reloadHandler 实现 POST /-/reload：重新读取并校验配置文件，并重建
+// discoveryManager 管理的服务发现 Discoverer；任一步失败都保留重载前的
+// 配置/Discoverer 并以 500 返回错误原因
+func reloadHandler(configStore *ConfigStore, discoveryManager *DiscoveryManager, reloadMetrics *ReloadMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "仅支持 POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := reloadConfig(configStore, discoveryManager, reloadMetrics, "收到 /-/reload 请求"); err != nil {
+			http.Error(w, fmt.Sprintf("配置重载失败：%v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "配置重载成功")
+	}
+}
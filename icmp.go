@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// ICMPParams 是 type 为 "icmp" 的 URLItem 的参数块
+type ICMPParams struct {
+	PayloadSize int `json:"payload_size"`
+}
+
+// ICMPProber 实现了 Prober 接口：发送一个 ICMP echo request 并等待回复。
+// 需要 CAP_NET_RAW 权限（或以 root 身份运行）才能打开原始套接字
+type ICMPProber struct{}
+
+// Probe 实现 Prober 接口
+func (p *ICMPProber) Probe(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	result := ProbeResult{Durations: make(map[string]float64)}
+
+	params := ICMPParams{}
+	if item.ICMP != nil {
+		params = *item.ICMP
+	}
+
+	addr, err := net.ResolveIPAddr("ip4", item.URL)
+	if err != nil {
+		return result, err
+	}
+
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return result, fmt.Errorf("打开 ICMP 套接字失败（可能缺少 CAP_NET_RAW 权限）：%w", err)
+	}
+	defer conn.Close()
+
+	wantID := os.Getpid() & 0xffff
+	wantSeq := 1
+	payload := make([]byte, params.PayloadSize)
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   wantID,
+			Seq:  wantSeq,
+			Data: payload,
+		},
+	}
+	wb, err := msg.Marshal(nil)
+	if err != nil {
+		return result, err
+	}
+
+	pc := conn.IPv4PacketConn()
+	_ = pc.SetControlMessage(ipv4.FlagTTL, true)
+
+	start := time.Now()
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return result, err
+	}
+	if _, err := conn.WriteTo(wb, addr); err != nil {
+		return result, err
+	}
+
+	// 原始 ip4:icmp 套接字会收到发给本机的所有 ICMP 流量，而不只是这次探测
+	// 的回复；并发探测多个目标时必须校验回复的来源地址以及 echo 的 ID/Seq，
+	// 否则可能把另一个 goroutine 的回复错误地当成当前目标的结果
+	rb := make([]byte, 1500)
+	for {
+		n, cm, peer, err := pc.ReadFrom(rb)
+		if err != nil {
+			return result, err
+		}
+		if !icmpPeerMatches(peer, addr) {
+			continue
+		}
+
+		rm, err := icmp.ParseMessage(1, rb[:n])
+		if err != nil {
+			continue
+		}
+		echo, ok := rm.Body.(*icmp.Echo)
+		if !ok || echo.ID != wantID || echo.Seq != wantSeq {
+			continue
+		}
+
+		result.Durations[phaseConnect] = time.Since(start).Seconds()
+		result.Success = rm.Type == ipv4.ICMPTypeEchoReply
+		result.HasICMPInfo = true
+		if cm != nil {
+			result.ICMPReplyTTL = cm.TTL
+		}
+		return result, nil
+	}
+}
+
+// icmpPeerMatches 判断收到的回复是否确实来自 want
+func icmpPeerMatches(peer net.Addr, want *net.IPAddr) bool {
+	ipAddr, ok := peer.(*net.IPAddr)
+	if !ok {
+		return false
+	}
+	return ipAddr.IP.Equal(want.IP)
+}
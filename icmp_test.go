@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestICMPPeerMatches(t *testing.T) {
+	want := &net.IPAddr{IP: net.ParseIP("10.0.0.1")}
+
+	if !icmpPeerMatches(&net.IPAddr{IP: net.ParseIP("10.0.0.1")}, want) {
+		t.Fatalf("a reply from the dialed address must be accepted")
+	}
+	if icmpPeerMatches(&net.IPAddr{IP: net.ParseIP("10.0.0.2")}, want) {
+		t.Fatalf("a reply from a different host must be rejected to avoid cross-target contamination")
+	}
+	if icmpPeerMatches(&net.UDPAddr{IP: net.ParseIP("10.0.0.1")}, want) {
+		t.Fatalf("an unexpected net.Addr implementation must be rejected")
+	}
+}
@@ -1,85 +1,83 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/tls"
 	"flag"
-	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// URLConfig 定义配置文件结构
-type URLConfig struct {
-	URLs     []URLItem `json:"urls"`
-	Settings Settings  `json:"settings"`
-}
-
-// URLItem 定义单个 URL 的结构
-type URLItem struct {
-	URL string `json:"url"`
-	IP  string `json:"ip"`
-}
-
-// Settings 定义全局设置的结构
-type Settings struct {
-	UpdateFreq int `json:"update_freq"`
-	Timeout    int `json:"timeout"`
-}
-
-// AuthConfig 定义认证配置文件结构
-type AuthConfig struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
 func main() {
 	// 定义配置文件路径和监听端口
-	configPath := flag.String("config", "url.json", "配置文件路径")
-	authPath := flag.String("auth", "auth.json", "认证配置文件路径")
+	configPath := flag.String("config", "url.json", "配置文件路径（支持 JSON 或 YAML）")
+	webConfigPath := flag.String("web.config.file", "web.yml", "Web 安全配置文件路径（TLS、basic-auth、IP allow-list）")
 	listenAddr := flag.String("addr", ":9119", "监听地址和端口")
 	flag.Parse()
 
-	// 读取 URL 配置文件
-	config, err := loadConfig(*configPath)
+	// 读取 URL 配置文件，并包装为可热重载的 ConfigStore
+	configStore, err := NewConfigStore(*configPath)
 	if err != nil {
 		log.Fatalf("加载配置文件失败：%v", err)
 	}
 
-	// 读取认证配置文件
-	authConfig, err := loadAuthConfig(*authPath)
+	// 读取 Web 安全配置文件
+	webConfig, err := loadWebConfig(*webConfigPath)
 	if err != nil {
-		log.Fatalf("加载认证配置文件失败：%v", err)
+		log.Fatalf("加载 Web 安全配置文件失败：%v", err)
 	}
 
-	// 初始化 Prometheus 指标
-	httpStatusCode := prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "http_status_code",
-			Help: "HTTP 状态码",
-		},
-		[]string{"url", "ip"},
-	)
+	ipRange, err := parseIPRange(webConfig.IPRange)
+	if err != nil {
+		log.Fatalf("解析 ip_range 失败：%v", err)
+	}
 
-	// 注册指标
-	prometheus.MustRegister(httpStatusCode)
+	// 初始化 Prometheus 指标，并注册到一个不含 Go/process 默认采集器的 Registry
+	config := configStore.Load()
+	var extraLabels []string
+	if config.Discovery != nil {
+		extraLabels = config.Discovery.ExtraLabels
+	}
+	metrics := NewMetrics(config.Settings.DurationBuckets, extraLabels)
+	registry := prometheus.NewRegistry()
+	metrics.Register(registry)
+
+	// 启动配置的服务发现子系统（file_sd、consul_sd），发现的目标集合会与
+	// url.json 中的静态目标合并后一起参与定时巡检。discoveryManager 同时
+	// 被 reloadConfig 复用，使得 discovery.* 配置随 /-/reload、SIGHUP 一并生效
+	discoveryManager := NewDiscoveryManager()
+	defer discoveryManager.Stop()
+	if err := discoveryManager.Reload(config.Discovery); err != nil {
+		log.Fatalf("启动服务发现失败：%v", err)
+	}
+	targetStore := discoveryManager.Store()
 
-	// 定期更新指标
+	reloadMetrics := NewReloadMetrics()
+	reloadMetrics.Register(registry)
+	reloadMetrics.RecordSuccess()
+
+	// 定期更新指标，通过有限并发的 worker 池对所有目标发起探测；目标集合
+	// 是 url.json 中的静态条目与服务发现产出的动态目标的合并结果。
+	// 当配置中禁用了定时巡检时，仅保留按需的 /probe 端点
 	go func() {
 		for {
-			for _, item := range config.URLs {
-				statusCode, err := checkURL(item.URL, config.Settings.Timeout)
-				if err != nil {
-					log.Printf("检测 URL %s 失败：%v 返回码： 000", item.URL, err)
-					httpStatusCode.WithLabelValues(item.URL, item.IP).Set(0)
-					continue
+			config := configStore.Load()
+			if !config.Settings.DisableScheduledProbing {
+				timeout := time.Duration(config.Settings.Timeout) * time.Second
+				concurrency := config.Settings.Concurrency
+				if concurrency <= 0 {
+					concurrency = defaultConcurrency
 				}
-
-				// 更新指标
-				httpStatusCode.WithLabelValues(item.URL, item.IP).Set(float64(statusCode))
+				urls := append(append([]URLItem{}, config.URLs...), targetStore.Load()...)
+				probeAll(metrics, urls, timeout, concurrency)
 			}
 
 			// 每隔指定的时间间隔更新一次指标
@@ -87,76 +85,123 @@ func main() {
 		}
 	}()
 
+	// SIGHUP 触发配置热重载，校验失败时保留旧配置
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	go func() {
+		for range reloadSignal {
+			reloadConfig(configStore, discoveryManager, reloadMetrics, "收到 SIGHUP")
+		}
+	}()
+
+	// 配置了 push.url 时，定期把 registry 中的指标推送到 Pushgateway，
+	// 适用于 Prometheus 无法反向抓取的短生命周期场景；SIGTERM 时优雅停止，
+	// 若配置了 delete_on_shutdown 则先删除 Pushgateway 上的分组
+	pushCtx, cancelPush := context.WithCancel(context.Background())
+	pushDone := make(chan struct{})
+	hasPusher := config.Push != nil && config.Push.URL != ""
+	if hasPusher {
+		pusher := NewPusher(*config.Push, registry)
+		go pusher.Run(pushCtx, pushDone)
+	} else {
+		close(pushDone)
+	}
+
+	termSignal := make(chan os.Signal, 1)
+	signal.Notify(termSignal, syscall.SIGTERM)
+	go func() {
+		<-termSignal
+		cancelPush()
+		<-pushDone
+		os.Exit(0)
+	}()
+
 	// 创建一个自定义的指标过滤器
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		registry := prometheus.NewRegistry()
-		registry.MustRegister(httpStatusCode)
-
 		// 使用 promhttp.HandlerFor 来处理过滤后的指标
 		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
 	})
 
-	// 创建一个带 Basic Auth 的 /metrics 路径
-	http.Handle("/metrics", basicAuthMiddleware(authConfig.Username, authConfig.Password, handler))
-
-	// 启动 HTTP 服务
-	log.Printf("开始监听 %s，更新频率为每 %d 秒，超时时间为 %d 秒", *listenAddr, config.Settings.UpdateFreq, config.Settings.Timeout)
-	if err := http.ListenAndServe(*listenAddr, nil); err != nil {
-		log.Fatalf("启动 HTTP 服务失败：%v", err)
+	// 依次套上 IP allow-list 与 bcrypt basic-auth 校验
+	http.Handle("/metrics", secureHandler(webConfig, ipRange, handler))
+	// 按需探测端点：/probe?target=<url>&module=<name>，供 Prometheus 的
+	// relabel_configs 驱动，无需预先写入 url.json
+	http.Handle("/probe", secureHandler(webConfig, ipRange, probeHandler(configStore)))
+	// 手动触发配置热重载的端点
+	http.Handle("/-/reload", secureHandler(webConfig, ipRange, reloadHandler(configStore, discoveryManager, reloadMetrics)))
+
+	// 启动 HTTP 服务：配置了 tls_server_config 时使用 TLS
+	server := &http.Server{Addr: *listenAddr}
+	if webConfig.HTTPServerConfig != nil && !webConfig.HTTPServerConfig.HTTP2 {
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
 	}
-}
 
-func loadConfig(path string) (*URLConfig, error) {
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+	if webConfig.usesTLS() {
+		tlsConfig, err := buildServerTLSConfig(webConfig.TLSServerConfig)
+		if err != nil {
+			log.Fatalf("构建 TLS 配置失败：%v", err)
+		}
+		server.TLSConfig = tlsConfig
 
-	var config URLConfig
-	err = json.Unmarshal(data, &config)
-	if err != nil {
-		return nil, err
+		log.Printf("开始通过 TLS 监听 %s，更新频率为每 %d 秒，超时时间为 %d 秒", *listenAddr, config.Settings.UpdateFreq, config.Settings.Timeout)
+		if err := server.ListenAndServeTLS(webConfig.TLSServerConfig.CertFile, webConfig.TLSServerConfig.KeyFile); err != nil {
+			log.Fatalf("启动 HTTPS 服务失败：%v", err)
+		}
+		return
 	}
 
-	return &config, nil
+	log.Printf("开始监听 %s，更新频率为每 %d 秒，超时时间为 %d 秒", *listenAddr, config.Settings.UpdateFreq, config.Settings.Timeout)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("启动 HTTP 服务失败：%v", err)
+	}
 }
 
-func loadAuthConfig(path string) (*AuthConfig, error) {
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, err
+// reloadConfig 执行一次配置热重载并记录结果，reason 用于日志标识触发来源。
+// 配置文件重新加载、校验通过之后，还会用新配置中的 discovery.* 重建
+// discoveryManager 管理的 Discoverer，使服务发现配置与 url.json/modules
+// 具备同样的热重载能力，而不是只在进程启动时生效一次
+func reloadConfig(configStore *ConfigStore, discoveryManager *DiscoveryManager, reloadMetrics *ReloadMetrics, reason string) error {
+	if err := configStore.Reload(); err != nil {
+		log.Printf("%s：配置热加载失败：%v", reason, err)
+		reloadMetrics.RecordFailure()
+		return err
 	}
 
-	var authConfig AuthConfig
-	err = json.Unmarshal(data, &authConfig)
-	if err != nil {
-		return nil, err
+	if err := discoveryManager.Reload(configStore.Load().Discovery); err != nil {
+		log.Printf("%s：服务发现重建失败，继续使用重建前的 Discoverer：%v", reason, err)
+		reloadMetrics.RecordFailure()
+		return err
 	}
 
-	return &authConfig, nil
+	log.Printf("%s：配置热加载成功", reason)
+	reloadMetrics.RecordSuccess()
+	return nil
 }
 
-func checkURL(url string, timeout int) (int, error) {
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
-	resp, err := client.Get(url)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
+// probeAll 对 urls 中的每一项发起一次探测，并发度由 concurrency 限制；
+// 每一项根据 Type 选用对应的 Prober 实现
+func probeAll(metrics *Metrics, urls []URLItem, timeout time.Duration, concurrency int) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, item := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(item URLItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := probeItem(item, timeout)
+			if err != nil {
+				log.Printf("检测 %s 失败：%v", item.URL, err)
+				metrics.ObserveFailure(item)
+				return
+			}
 
-	return resp.StatusCode, nil
-}
+			metrics.Observe(item, result)
+		}(item)
+	}
 
-func basicAuthMiddleware(username, password string, next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user, pass, ok := r.BasicAuth()
-		if !ok || user != username || pass != password {
-			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+	wg.Wait()
 }
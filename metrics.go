@@ -0,0 +1,314 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// baseProbeLabels 是所有 probe_* 指标固定携带的标签
+var baseProbeLabels = []string{"url", "ip"}
+
+// Metrics 汇总了探测循环需要填充的全部 Prometheus 指标
+type Metrics struct {
+	extraLabels []string
+
+	Success               *prometheus.GaugeVec
+	Duration              *prometheus.HistogramVec
+	HTTPStatusCode        *prometheus.GaugeVec
+	HTTPContentLength     *prometheus.GaugeVec
+	HTTPRedirects         *prometheus.GaugeVec
+	HTTPVersion           *prometheus.GaugeVec
+	HTTPSSL               *prometheus.GaugeVec
+	SSLEarliestCertExpiry *prometheus.GaugeVec
+	DNSAnswerRRs          *prometheus.GaugeVec
+	ICMPReplyTTL          *prometheus.GaugeVec
+}
+
+// NewMetrics 创建探测指标，durationBuckets 为空时使用 Prometheus 默认分桶。
+// extraLabels 通常来自服务发现的 relabel 结果（DiscoveryConfig.ExtraLabels），
+// 每个名字都会成为全部 probe_* 指标的一个额外标签；未发现目标提供的值默认为空字符串。
+// 返回的指标尚未注册到任何 Registerer，调用方需要自行 Register。
+func NewMetrics(durationBuckets []float64, extraLabels []string) *Metrics {
+	if len(durationBuckets) == 0 {
+		durationBuckets = prometheus.DefBuckets
+	}
+
+	probeLabels := append(append([]string{}, baseProbeLabels...), extraLabels...)
+
+	m := &Metrics{
+		extraLabels: extraLabels,
+		Success: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_success",
+			Help: "探测是否成功（1 表示成功，0 表示失败）",
+		}, probeLabels),
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "probe_duration_seconds",
+			Help:    "探测各阶段（resolve/connect/tls/processing/transfer）耗时，单位秒",
+			Buckets: durationBuckets,
+		}, append(append([]string{}, probeLabels...), "phase")),
+		HTTPStatusCode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_http_status_code",
+			Help: "HTTP 响应状态码",
+		}, probeLabels),
+		HTTPContentLength: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_http_content_length",
+			Help: "HTTP 响应体长度，单位字节",
+		}, probeLabels),
+		HTTPRedirects: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_http_redirects",
+			Help: "请求过程中经历的跳转次数",
+		}, probeLabels),
+		HTTPVersion: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_http_version",
+			Help: "响应所使用的 HTTP 协议版本，如 1.1、2",
+		}, probeLabels),
+		HTTPSSL: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_http_ssl",
+			Help: "目标是否通过 TLS 提供服务（1 表示是）",
+		}, probeLabels),
+		SSLEarliestCertExpiry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_ssl_earliest_cert_expiry",
+			Help: "证书链中最早到期证书的过期时间（Unix 时间戳）",
+		}, probeLabels),
+		DNSAnswerRRs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_dns_answer_rrs",
+			Help: "DNS 响应中应答记录（answer）的数量",
+		}, probeLabels),
+		ICMPReplyTTL: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "probe_icmp_reply_ttl",
+			Help: "ICMP 回复报文的 IP TTL",
+		}, probeLabels),
+	}
+
+	return m
+}
+
+// Register 将全部指标注册到给定的 Registerer
+func (m *Metrics) Register(reg prometheus.Registerer) {
+	reg.MustRegister(
+		m.Success,
+		m.Duration,
+		m.HTTPStatusCode,
+		m.HTTPContentLength,
+		m.HTTPRedirects,
+		m.HTTPVersion,
+		m.HTTPSSL,
+		m.SSLEarliestCertExpiry,
+		m.DNSAnswerRRs,
+		m.ICMPReplyTTL,
+	)
+}
+
+// labelsFor 构建 item 对应的完整标签集合，包含固定的 url/ip 以及 m.extraLabels
+// 中列出的、取自 item.Labels 的额外标签（未提供时取空字符串）
+func (m *Metrics) labelsFor(item URLItem) prometheus.Labels {
+	labels := prometheus.Labels{"url": item.URL, "ip": item.IP}
+	for _, name := range m.extraLabels {
+		labels[name] = item.Labels[name]
+	}
+	return labels
+}
+
+// Observe 将一次探测结果写入各指标
+func (m *Metrics) Observe(item URLItem, result ProbeResult) {
+	labels := m.labelsFor(item)
+
+	if result.Success {
+		m.Success.With(labels).Set(1)
+	} else {
+		m.Success.With(labels).Set(0)
+	}
+	m.HTTPStatusCode.With(labels).Set(float64(result.StatusCode))
+	m.HTTPContentLength.With(labels).Set(float64(result.ContentLength))
+	m.HTTPRedirects.With(labels).Set(float64(result.Redirects))
+	m.HTTPVersion.With(labels).Set(result.HTTPVersion)
+	m.SSLEarliestCertExpiry.With(labels).Set(result.SSLEarliestCertExpiry)
+
+	if result.HasSSL {
+		m.HTTPSSL.With(labels).Set(1)
+	} else {
+		m.HTTPSSL.With(labels).Set(0)
+	}
+
+	if result.HasDNSInfo {
+		m.DNSAnswerRRs.With(labels).Set(float64(result.DNSAnswerRRs))
+	}
+	if result.HasICMPInfo {
+		m.ICMPReplyTTL.With(labels).Set(float64(result.ICMPReplyTTL))
+	}
+
+	durationLabels := m.labelsFor(item)
+	for phase, seconds := range result.Durations {
+		durationLabels["phase"] = phase
+		m.Duration.With(durationLabels).Observe(seconds)
+	}
+}
+
+// ObserveFailure 在探测发生传输层错误时写入指标。由于 Metrics 使用长期存活的
+// GaugeVec（不同于 /probe 端点每次请求一个全新 Registry 的 AdHocMetrics），
+// 必须把上一次成功探测写下的全部数值一并清零，否则目标下线后
+// probe_http_content_length、probe_ssl_earliest_cert_expiry 等指标会一直
+// 停留在旧值，与 probe_success=0 同时出现会产生误导
+func (m *Metrics) ObserveFailure(item URLItem) {
+	labels := m.labelsFor(item)
+	m.Success.With(labels).Set(0)
+	m.HTTPStatusCode.With(labels).Set(0)
+	m.HTTPContentLength.With(labels).Set(0)
+	m.HTTPRedirects.With(labels).Set(0)
+	m.HTTPVersion.With(labels).Set(0)
+	m.HTTPSSL.With(labels).Set(0)
+	m.SSLEarliestCertExpiry.With(labels).Set(0)
+	m.DNSAnswerRRs.With(labels).Set(0)
+	m.ICMPReplyTTL.With(labels).Set(0)
+}
+
+// AdHocMetrics 汇总 /probe 端点单次探测所需的指标。与 Metrics 不同，这里的指标
+// 不携带 url/ip 标签：每次请求都使用一个全新的 Registry，target 本身就是唯一的，
+// 与 blackbox_exporter 的 /probe 导出格式保持一致
+type AdHocMetrics struct {
+	Success               prometheus.Gauge
+	Duration              *prometheus.HistogramVec
+	HTTPStatusCode        prometheus.Gauge
+	HTTPContentLength     prometheus.Gauge
+	HTTPRedirects         prometheus.Gauge
+	HTTPVersion           prometheus.Gauge
+	HTTPSSL               prometheus.Gauge
+	SSLEarliestCertExpiry prometheus.Gauge
+	DNSAnswerRRs          prometheus.Gauge
+	ICMPReplyTTL          prometheus.Gauge
+}
+
+// NewAdHocMetrics 创建 /probe 端点使用的指标，durationBuckets 为空时使用默认分桶
+func NewAdHocMetrics(durationBuckets []float64) *AdHocMetrics {
+	if len(durationBuckets) == 0 {
+		durationBuckets = prometheus.DefBuckets
+	}
+
+	return &AdHocMetrics{
+		Success: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_success",
+			Help: "探测是否成功（1 表示成功，0 表示失败）",
+		}),
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "probe_duration_seconds",
+			Help:    "探测各阶段（resolve/connect/tls/processing/transfer）耗时，单位秒",
+			Buckets: durationBuckets,
+		}, []string{"phase"}),
+		HTTPStatusCode: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_http_status_code",
+			Help: "HTTP 响应状态码",
+		}),
+		HTTPContentLength: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_http_content_length",
+			Help: "HTTP 响应体长度，单位字节",
+		}),
+		HTTPRedirects: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_http_redirects",
+			Help: "请求过程中经历的跳转次数",
+		}),
+		HTTPVersion: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_http_version",
+			Help: "响应所使用的 HTTP 协议版本，如 1.1、2",
+		}),
+		HTTPSSL: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_http_ssl",
+			Help: "目标是否通过 TLS 提供服务（1 表示是）",
+		}),
+		SSLEarliestCertExpiry: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_ssl_earliest_cert_expiry",
+			Help: "证书链中最早到期证书的过期时间（Unix 时间戳）",
+		}),
+		DNSAnswerRRs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_dns_answer_rrs",
+			Help: "DNS 响应中应答记录（answer）的数量",
+		}),
+		ICMPReplyTTL: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "probe_icmp_reply_ttl",
+			Help: "ICMP 回复报文的 IP TTL",
+		}),
+	}
+}
+
+// Register 将全部指标注册到给定的 Registerer
+func (m *AdHocMetrics) Register(reg prometheus.Registerer) {
+	reg.MustRegister(
+		m.Success,
+		m.Duration,
+		m.HTTPStatusCode,
+		m.HTTPContentLength,
+		m.HTTPRedirects,
+		m.HTTPVersion,
+		m.HTTPSSL,
+		m.SSLEarliestCertExpiry,
+		m.DNSAnswerRRs,
+		m.ICMPReplyTTL,
+	)
+}
+
+// Observe 将一次探测结果写入各指标
+func (m *AdHocMetrics) Observe(result ProbeResult) {
+	if result.Success {
+		m.Success.Set(1)
+	} else {
+		m.Success.Set(0)
+	}
+	m.HTTPStatusCode.Set(float64(result.StatusCode))
+	m.HTTPContentLength.Set(float64(result.ContentLength))
+	m.HTTPRedirects.Set(float64(result.Redirects))
+	m.HTTPVersion.Set(result.HTTPVersion)
+	m.SSLEarliestCertExpiry.Set(result.SSLEarliestCertExpiry)
+
+	if result.HasSSL {
+		m.HTTPSSL.Set(1)
+	} else {
+		m.HTTPSSL.Set(0)
+	}
+
+	if result.HasDNSInfo {
+		m.DNSAnswerRRs.Set(float64(result.DNSAnswerRRs))
+	}
+	if result.HasICMPInfo {
+		m.ICMPReplyTTL.Set(float64(result.ICMPReplyTTL))
+	}
+
+	for phase, seconds := range result.Durations {
+		m.Duration.WithLabelValues(phase).Observe(seconds)
+	}
+}
+
+// ReloadMetrics 跟踪配置热重载（SIGHUP / POST /-/reload）的结果
+type ReloadMetrics struct {
+	LastReloadSuccessTimestamp prometheus.Gauge
+	LastReloadSuccessful       prometheus.Gauge
+}
+
+// NewReloadMetrics 创建配置热重载指标
+func NewReloadMetrics() *ReloadMetrics {
+	return &ReloadMetrics{
+		LastReloadSuccessTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "urlprobe_config_last_reload_success_timestamp_seconds",
+			Help: "最近一次配置重载成功的 Unix 时间戳",
+		}),
+		LastReloadSuccessful: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "urlprobe_config_last_reload_successful",
+			Help: "最近一次配置重载是否成功（1 表示成功，0 表示失败）",
+		}),
+	}
+}
+
+// Register 将指标注册到给定的 Registerer
+func (m *ReloadMetrics) Register(reg prometheus.Registerer) {
+	reg.MustRegister(m.LastReloadSuccessTimestamp, m.LastReloadSuccessful)
+}
+
+// RecordSuccess 记录一次成功的配置重载
+func (m *ReloadMetrics) RecordSuccess() {
+	m.LastReloadSuccessTimestamp.Set(float64(time.Now().Unix()))
+	m.LastReloadSuccessful.Set(1)
+}
+
+// RecordFailure 记录一次失败的配置重载
+func (m *ReloadMetrics) RecordFailure() {
+	m.LastReloadSuccessful.Set(0)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestObserveFailureResetsStaleGauges 回归测试：一次成功探测之后紧接着的失败
+// 探测必须把全部 probe_* 指标清零，而不是只清零 probe_success/probe_http_status_code，
+// 否则 probe_ssl_earliest_cert_expiry 等指标会在目标下线后继续暴露陈旧数据
+func TestObserveFailureResetsStaleGauges(t *testing.T) {
+	metrics := NewMetrics(nil, nil)
+	item := URLItem{URL: "http://example.com", IP: "1.2.3.4"}
+
+	metrics.Observe(item, ProbeResult{
+		Success:               true,
+		StatusCode:            200,
+		ContentLength:         1234,
+		Redirects:             2,
+		HTTPVersion:           1.1,
+		HasSSL:                true,
+		SSLEarliestCertExpiry: 1893456000,
+		HasDNSInfo:            true,
+		DNSAnswerRRs:          3,
+		HasICMPInfo:           true,
+		ICMPReplyTTL:          64,
+		Durations:             map[string]float64{},
+	})
+
+	metrics.ObserveFailure(item)
+
+	labels := metrics.labelsFor(item)
+	checks := map[string]float64{
+		"probe_success":                  0,
+		"probe_http_status_code":         0,
+		"probe_http_content_length":      0,
+		"probe_http_redirects":           0,
+		"probe_http_version":             0,
+		"probe_http_ssl":                 0,
+		"probe_ssl_earliest_cert_expiry": 0,
+		"probe_dns_answer_rrs":           0,
+		"probe_icmp_reply_ttl":           0,
+	}
+
+	for name := range checks {
+		var got float64
+		switch name {
+		case "probe_success":
+			got = testutil.ToFloat64(metrics.Success.With(labels))
+		case "probe_http_status_code":
+			got = testutil.ToFloat64(metrics.HTTPStatusCode.With(labels))
+		case "probe_http_content_length":
+			got = testutil.ToFloat64(metrics.HTTPContentLength.With(labels))
+		case "probe_http_redirects":
+			got = testutil.ToFloat64(metrics.HTTPRedirects.With(labels))
+		case "probe_http_version":
+			got = testutil.ToFloat64(metrics.HTTPVersion.With(labels))
+		case "probe_http_ssl":
+			got = testutil.ToFloat64(metrics.HTTPSSL.With(labels))
+		case "probe_ssl_earliest_cert_expiry":
+			got = testutil.ToFloat64(metrics.SSLEarliestCertExpiry.With(labels))
+		case "probe_dns_answer_rrs":
+			got = testutil.ToFloat64(metrics.DNSAnswerRRs.With(labels))
+		case "probe_icmp_reply_ttl":
+			got = testutil.ToFloat64(metrics.ICMPReplyTTL.With(labels))
+		}
+		if got != 0 {
+			t.Errorf("%s = %v after ObserveFailure, want 0 (stale value was not reset)", name, got)
+		}
+	}
+}
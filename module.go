@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Module 定义了一个可通过 /probe?module=<name> 引用的探测配置，
+// 风格上对齐 blackbox_exporter 的 module 概念。Prober 选择实际使用的协议
+// （""/"http"、"tcp"、"icmp"、"dns"、"grpc"），对应类型的参数块仅在该类型
+// 下生效，与 URLItem 的 TCP/ICMP/DNS/GRPC 字段保持同一结构
+type Module struct {
+	Prober  string     `json:"prober"`
+	Timeout int        `json:"timeout"`
+	HTTP    HTTPModule `json:"http"`
+
+	TCP  *TCPParams  `json:"tcp,omitempty"`
+	ICMP *ICMPParams `json:"icmp,omitempty"`
+	DNS  *DNSParams  `json:"dns,omitempty"`
+	GRPC *GRPCParams `json:"grpc,omitempty"`
+}
+
+// HTTPModule 是 Module 中 HTTP 探测专用的参数块
+type HTTPModule struct {
+	Method                     string            `json:"method"`
+	Headers                    map[string]string `json:"headers"`
+	ValidStatusCodes           []int             `json:"valid_status_codes"`
+	FollowRedirects            bool              `json:"follow_redirects"`
+	FailIfBodyNotMatchesRegexp string            `json:"fail_if_body_not_matches_regexp"`
+	TLSConfig                  TLSConfig         `json:"tls_config"`
+}
+
+// TLSConfig 描述探测时使用的 TLS 客户端设置
+type TLSConfig struct {
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+}
+
+// defaultModuleTimeout 在 module 未指定 timeout 时使用
+const defaultModuleTimeout = 10
+
+// moduleProberType 返回 module 实际使用的探测协议，未配置 prober 时默认为 "http"
+func moduleProberType(module Module) string {
+	if module.Prober == "" {
+		return "http"
+	}
+	return module.Prober
+}
+
+// moduleTargetItem 把 /probe 请求的 target 与 module 中对应协议的参数块
+// 组装为一个 URLItem，供非 HTTP 的 Prober 实现使用
+func moduleTargetItem(module Module, target string) URLItem {
+	return URLItem{
+		URL:  target,
+		Type: moduleProberType(module),
+		TCP:  module.TCP,
+		ICMP: module.ICMP,
+		DNS:  module.DNS,
+		GRPC: module.GRPC,
+	}
+}
+
+// buildProbeOptions 将 Module 转换为 Prober.Probe 可以使用的 ProbeOptions，
+// 并编译其中的正则表达式；仅适用于 prober 为 "http" 的 module
+func buildProbeOptions(module Module) (ProbeOptions, error) {
+	opts := ProbeOptions{
+		Method:             module.HTTP.Method,
+		Headers:            module.HTTP.Headers,
+		FollowRedirects:    module.HTTP.FollowRedirects,
+		ValidStatusCodes:   module.HTTP.ValidStatusCodes,
+		InsecureSkipVerify: module.HTTP.TLSConfig.InsecureSkipVerify,
+	}
+	if opts.Method == "" {
+		opts.Method = "GET"
+	}
+
+	if module.HTTP.FailIfBodyNotMatchesRegexp != "" {
+		re, err := regexp.Compile(module.HTTP.FailIfBodyNotMatchesRegexp)
+		if err != nil {
+			return ProbeOptions{}, fmt.Errorf("编译 module 正则失败：%w", err)
+		}
+		opts.BodyRegexp = re
+	}
+
+	return opts, nil
+}
+
+// moduleTimeout 返回 module 配置的超时时间，未配置时使用默认值
+func moduleTimeout(module Module) time.Duration {
+	timeout := module.Timeout
+	if timeout <= 0 {
+		timeout = defaultModuleTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
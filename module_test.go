@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestModuleProberTypeDefaultsToHTTP(t *testing.T) {
+	if got := moduleProberType(Module{}); got != "http" {
+		t.Fatalf("empty Prober should default to http, got %q", got)
+	}
+	if got := moduleProberType(Module{Prober: "tcp"}); got != "tcp" {
+		t.Fatalf("explicit Prober should be used as-is, got %q", got)
+	}
+}
+
+func TestModuleTargetItemCarriesProtocolParams(t *testing.T) {
+	module := Module{
+		Prober: "dns",
+		DNS:    &DNSParams{QueryName: "example.com."},
+	}
+
+	item := moduleTargetItem(module, "127.0.0.1:53")
+	if item.URL != "127.0.0.1:53" || item.Type != "dns" {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	if item.DNS == nil || item.DNS.QueryName != "example.com." {
+		t.Fatalf("module.DNS params should be carried through to the URLItem, got %+v", item.DNS)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"regexp"
+	"time"
+)
+
+// probe 阶段名称，用于 probe_duration_seconds 的 phase 标签
+const (
+	phaseResolve    = "resolve"
+	phaseConnect    = "connect"
+	phaseTLS        = "tls"
+	phaseProcessing = "processing"
+	phaseTransfer   = "transfer"
+)
+
+// ProbeResult 记录一次探测得到的全部指标数据。HTTP 探测会填充大部分字段，
+// 其余协议的 Prober 实现只填充各自相关的字段，其余保持零值
+type ProbeResult struct {
+	Success               bool
+	StatusCode            int
+	ContentLength         int64
+	Redirects             int
+	HTTPVersion           float64
+	HasSSL                bool
+	SSLEarliestCertExpiry float64
+	Durations             map[string]float64
+
+	// HasDNSInfo 为 true 时，DNSAnswerRRs 才会通过 probe_dns_answer_rrs 导出
+	HasDNSInfo   bool
+	DNSAnswerRRs int
+
+	// HasICMPInfo 为 true 时，ICMPReplyTTL 才会通过 probe_icmp_reply_ttl 导出
+	HasICMPInfo  bool
+	ICMPReplyTTL int
+}
+
+// ProbeOptions 控制一次 HTTP 探测的具体行为，既用于周期性巡检，
+// 也用于 /probe 端点按 module 发起的按需探测
+type ProbeOptions struct {
+	Method             string
+	Headers            map[string]string
+	FollowRedirects    bool
+	ValidStatusCodes   []int
+	BodyRegexp         *regexp.Regexp
+	InsecureSkipVerify bool
+}
+
+// DefaultProbeOptions 返回与历史行为一致的默认探测参数：
+// GET 请求、跟随跳转、状态码 200-399 视为成功
+func DefaultProbeOptions() ProbeOptions {
+	return ProbeOptions{
+		Method:          http.MethodGet,
+		FollowRedirects: true,
+	}
+}
+
+// HTTPProber 实现了 Prober 接口，对单个 URL 执行 HTTP 探测
+type HTTPProber struct{}
+
+// Probe 实现 Prober 接口，对 item.URL 使用默认探测参数执行一次探测，
+// 供周期性巡检循环使用；item.Type 必须是 "" 或 "http"
+func (p *HTTPProber) Probe(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	return p.ProbeWithOptions(item.URL, timeout, DefaultProbeOptions())
+}
+
+// ProbeWithOptions 按给定超时时间和 ProbeOptions 对 url 执行一次探测，返回
+// 各阶段耗时及状态信息。返回的 error 仅代表传输层失败（DNS、连接、超时等）；
+// HTTP 层的非预期状态码或正文不匹配通过 ProbeResult.Success 体现，而不会
+// 作为 error 返回。
+func (p *HTTPProber) ProbeWithOptions(url string, timeout time.Duration, opts ProbeOptions) (ProbeResult, error) {
+	result := ProbeResult{Durations: make(map[string]float64)}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify},
+		},
+	}
+	if !opts.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var dnsStart, connectStart, tlsStart, gotConnAt, firstByteAt time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			result.Durations[phaseResolve] = time.Since(dnsStart).Seconds()
+		},
+		ConnectStart: func(network, addr string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				result.Durations[phaseConnect] = time.Since(connectStart).Seconds()
+			}
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			result.Durations[phaseTLS] = time.Since(tlsStart).Seconds()
+			if err == nil && len(state.PeerCertificates) > 0 {
+				result.HasSSL = true
+				result.SSLEarliestCertExpiry = earliestCertExpiry(state)
+			}
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			gotConnAt = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			firstByteAt = time.Now()
+			if !gotConnAt.IsZero() {
+				result.Durations[phaseProcessing] = firstByteAt.Sub(gotConnAt).Seconds()
+			}
+		},
+	}
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return result, err
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Redirects = countRedirects(resp)
+	result.HTTPVersion = httpVersionNumber(resp.Proto)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	if !firstByteAt.IsZero() {
+		result.Durations[phaseTransfer] = time.Since(firstByteAt).Seconds()
+	}
+
+	result.ContentLength = resp.ContentLength
+	if result.ContentLength < 0 {
+		result.ContentLength = int64(len(body))
+	}
+
+	result.Success = statusCodeMatches(resp.StatusCode, opts.ValidStatusCodes) &&
+		bodyMatches(body, opts.BodyRegexp)
+
+	return result, nil
+}
+
+// statusCodeMatches 判断状态码是否满足要求：valid 为空时退回默认的 200-399 区间
+func statusCodeMatches(statusCode int, valid []int) bool {
+	if len(valid) == 0 {
+		return statusCode > 0 && statusCode < 400
+	}
+	for _, code := range valid {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyMatches 判断响应正文是否满足 regexp 要求：regexp 为 nil 时视为通过
+func bodyMatches(body []byte, re *regexp.Regexp) bool {
+	if re == nil {
+		return true
+	}
+	return re.Match(body)
+}
+
+// countRedirects 统计 resp.Request 链上经历的跳转次数
+func countRedirects(resp *http.Response) int {
+	count := 0
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		count++
+	}
+	return count
+}
+
+// httpVersionNumber 将 "HTTP/1.1"、"HTTP/2.0" 等协议字符串转换为数值
+func httpVersionNumber(proto string) float64 {
+	switch proto {
+	case "HTTP/2.0":
+		return 2.0
+	case "HTTP/1.0":
+		return 1.0
+	case "HTTP/1.1":
+		return 1.1
+	default:
+		return 0
+	}
+}
+
+// earliestCertExpiry 返回证书链中最早到期证书的过期时间（Unix 时间戳）
+func earliestCertExpiry(state tls.ConnectionState) float64 {
+	var earliest time.Time
+	for _, cert := range state.PeerCertificates {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	if earliest.IsZero() {
+		return 0
+	}
+	return float64(earliest.Unix())
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestStatusCodeMatches(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		valid      []int
+		want       bool
+	}{
+		{"default range accepts 200", 200, nil, true},
+		{"default range accepts 399", 399, nil, true},
+		{"default range rejects 400", 400, nil, false},
+		{"default range rejects 0", 0, nil, false},
+		{"explicit list accepts listed code", 404, []int{404, 410}, true},
+		{"explicit list rejects unlisted code", 500, []int{404, 410}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := statusCodeMatches(c.statusCode, c.valid); got != c.want {
+				t.Fatalf("statusCodeMatches(%d, %v) = %v, want %v", c.statusCode, c.valid, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBodyMatchesNilRegexp(t *testing.T) {
+	if !bodyMatches([]byte("anything"), nil) {
+		t.Fatalf("nil regexp should always match")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Prober 是所有探测协议实现需要满足的统一接口
+type Prober interface {
+	Probe(item URLItem, timeout time.Duration) (ProbeResult, error)
+}
+
+// probeItem 根据 item.Type 选择对应的 Prober 并执行一次探测，
+// item.Type 为空时视为 "http"
+func probeItem(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	prober, err := proberFor(item.Type)
+	if err != nil {
+		return ProbeResult{}, err
+	}
+	return prober.Probe(item, timeout)
+}
+
+// proberFor 返回 probeType 对应的 Prober 实现
+func proberFor(probeType string) (Prober, error) {
+	switch probeType {
+	case "", "http":
+		return &HTTPProber{}, nil
+	case "tcp":
+		return &TCPProber{}, nil
+	case "icmp":
+		return &ICMPProber{}, nil
+	case "dns":
+		return &DNSProber{}, nil
+	case "grpc":
+		return &GRPCProber{}, nil
+	default:
+		return nil, fmt.Errorf("未知的探测类型：%q", probeType)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// PushConfig 定义 Pushgateway 推送模式的配置。配置了 url 时，除了（或代替）
+// 被动提供 /metrics，探测器会定期把已采集到的指标推送到 Pushgateway，
+// 适用于 CI 任务、cron 定时巡检等 Prometheus 无法反向抓取的短生命周期场景
+type PushConfig struct {
+	URL              string            `json:"url" yaml:"url"`
+	Job              string            `json:"job" yaml:"job"`
+	Grouping         map[string]string `json:"grouping" yaml:"grouping"`
+	IntervalSeconds  int               `json:"interval" yaml:"interval"`
+	BasicAuth        *PushBasicAuth    `json:"basic_auth" yaml:"basic_auth"`
+	Method           string            `json:"method" yaml:"method"` // "push"（默认，PUT 全量替换）或 "add"（POST 合并）
+	DeleteOnShutdown bool              `json:"delete_on_shutdown" yaml:"delete_on_shutdown"`
+}
+
+// PushBasicAuth 是推送到 Pushgateway 时使用的 HTTP Basic Auth 凭据
+type PushBasicAuth struct {
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// defaultPushIntervalSeconds 是未在配置中指定 push.interval 时使用的推送间隔
+const defaultPushIntervalSeconds = 15
+
+// Pusher 定期把 registry 中的指标推送到 Pushgateway
+type Pusher struct {
+	pusher           *push.Pusher
+	interval         time.Duration
+	useAdd           bool
+	deleteOnShutdown bool
+}
+
+// NewPusher 根据 cfg 构建一个向 registry 推送的 Pusher
+func NewPusher(cfg PushConfig, registry *prometheus.Registry) *Pusher {
+	p := push.New(cfg.URL, cfg.Job).Gatherer(registry)
+	for name, value := range cfg.Grouping {
+		p = p.Grouping(name, value)
+	}
+	if cfg.BasicAuth != nil {
+		p = p.BasicAuth(cfg.BasicAuth.Username, cfg.BasicAuth.Password)
+	}
+
+	interval := time.Duration(cfg.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultPushIntervalSeconds * time.Second
+	}
+
+	return &Pusher{
+		pusher:           p,
+		interval:         interval,
+		useAdd:           strings.EqualFold(cfg.Method, "add"),
+		deleteOnShutdown: cfg.DeleteOnShutdown,
+	}
+}
+
+// Run 按配置的时间间隔持续推送指标，直到 ctx 被取消；取消后若配置了
+// delete_on_shutdown，会先删除 Pushgateway 上对应的分组，随后向 done 发出完成信号
+func (p *Pusher) Run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.pushOnce(); err != nil {
+				log.Printf("推送指标到 Pushgateway 失败：%v", err)
+			}
+		case <-ctx.Done():
+			if p.deleteOnShutdown {
+				if err := p.pusher.Delete(); err != nil {
+					log.Printf("删除 Pushgateway 分组失败：%v", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// pushOnce 推送一次当前指标，method 为 "add" 时使用合并语义（POST），
+// 否则使用默认的全量替换语义（PUT）
+func (p *Pusher) pushOnce() error {
+	if p.useAdd {
+		return p.pusher.Add()
+	}
+	return p.pusher.Push()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RelabelConfig 是 Prometheus relabel_configs 的一个精简实现：按顺序对发现
+// 目标的 label 集合做 keep/drop/replace，用于把 __meta_consul_service 等
+// 发现元数据改写为最终暴露的 url、ip、type 及自定义指标标签
+type RelabelConfig struct {
+	SourceLabels []string `json:"source_labels" yaml:"source_labels"`
+	Separator    string   `json:"separator" yaml:"separator"`
+	Regex        string   `json:"regex" yaml:"regex"`
+	TargetLabel  string   `json:"target_label" yaml:"target_label"`
+	Replacement  string   `json:"replacement" yaml:"replacement"`
+	Action       string   `json:"action" yaml:"action"` // keep | drop | replace，默认 replace
+}
+
+// defaultRelabelSeparator 是拼接多个 source_labels 取值时使用的默认分隔符
+const defaultRelabelSeparator = ";"
+
+// Relabel 依次对 labels 应用 configs，返回改写后的 label 集合；
+// 第二个返回值为 false 时表示目标被某条 keep/drop 规则丢弃
+func Relabel(labels map[string]string, configs []RelabelConfig) (map[string]string, bool) {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+
+	for _, cfg := range configs {
+		var keep bool
+		result, keep = applyRelabel(result, cfg)
+		if !keep {
+			return nil, false
+		}
+	}
+
+	return result, true
+}
+
+// applyRelabel 应用单条 relabel 规则
+func applyRelabel(labels map[string]string, cfg RelabelConfig) (map[string]string, bool) {
+	separator := cfg.Separator
+	if separator == "" {
+		separator = defaultRelabelSeparator
+	}
+
+	values := make([]string, len(cfg.SourceLabels))
+	for i, name := range cfg.SourceLabels {
+		values[i] = labels[name]
+	}
+	value := strings.Join(values, separator)
+
+	pattern := cfg.Regex
+	if pattern == "" {
+		pattern = ".*"
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return labels, true
+	}
+
+	switch cfg.Action {
+	case "keep":
+		return labels, re.MatchString(value)
+	case "drop":
+		return labels, !re.MatchString(value)
+	default: // "replace"
+		if cfg.TargetLabel == "" {
+			return labels, true
+		}
+		if !re.MatchString(value) {
+			return labels, true
+		}
+		replaced := make(map[string]string, len(labels)+1)
+		for k, v := range labels {
+			replaced[k] = v
+		}
+		replaced[cfg.TargetLabel] = re.ReplaceAllString(value, cfg.Replacement)
+		return replaced, true
+	}
+}
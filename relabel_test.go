@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestRelabelKeep(t *testing.T) {
+	labels := map[string]string{"__meta_consul_tags": ",prod,web,"}
+	configs := []RelabelConfig{
+		{SourceLabels: []string{"__meta_consul_tags"}, Regex: ".*,prod,.*", Action: "keep"},
+	}
+
+	result, keep := Relabel(labels, configs)
+	if !keep {
+		t.Fatalf("expected target to be kept")
+	}
+	if result["__meta_consul_tags"] != labels["__meta_consul_tags"] {
+		t.Fatalf("keep should not modify labels, got %v", result)
+	}
+}
+
+func TestRelabelDrop(t *testing.T) {
+	labels := map[string]string{"__meta_consul_tags": ",staging,"}
+	configs := []RelabelConfig{
+		{SourceLabels: []string{"__meta_consul_tags"}, Regex: ".*,prod,.*", Action: "drop"},
+	}
+
+	if _, keep := Relabel(labels, configs); !keep {
+		t.Fatalf("target without the prod tag should not be dropped by this rule")
+	}
+
+	configs[0].Action = "keep"
+	if _, keep := Relabel(labels, configs); keep {
+		t.Fatalf("target without the prod tag should not be kept")
+	}
+}
+
+func TestRelabelReplace(t *testing.T) {
+	labels := map[string]string{"__meta_consul_service": "checkout", "__address__": "10.0.0.1:8080"}
+	configs := []RelabelConfig{
+		{SourceLabels: []string{"__meta_consul_service"}, Regex: "(.*)", TargetLabel: "service", Replacement: "$1", Action: "replace"},
+	}
+
+	result, keep := Relabel(labels, configs)
+	if !keep {
+		t.Fatalf("replace should never drop a target")
+	}
+	if result["service"] != "checkout" {
+		t.Fatalf("expected service label to be set to checkout, got %q", result["service"])
+	}
+	// 原始 labels 不应被就地修改
+	if _, ok := labels["service"]; ok {
+		t.Fatalf("Relabel must not mutate the input label map")
+	}
+}
+
+func TestRelabelTargetsDropsUnmatched(t *testing.T) {
+	targets := []map[string]string{
+		{"__address__": "10.0.0.1:80", "__meta_consul_tags": ",prod,"},
+		{"__address__": "10.0.0.2:80", "__meta_consul_tags": ",staging,"},
+	}
+	configs := []RelabelConfig{
+		{SourceLabels: []string{"__meta_consul_tags"}, Regex: ".*,prod,.*", Action: "keep"},
+	}
+
+	items := relabelTargets(targets, configs, nil)
+	if len(items) != 1 {
+		t.Fatalf("expected exactly 1 target to survive the keep rule, got %d", len(items))
+	}
+	if items[0].IP != "10.0.0.1:80" {
+		t.Fatalf("unexpected surviving target: %+v", items[0])
+	}
+}
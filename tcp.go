@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+)
+
+// TCPParams 是 type 为 "tcp" 的 URLItem 的参数块。URLItem.URL 被当作
+// "host:port" 地址使用
+type TCPParams struct {
+	TLS                bool   `json:"tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	BannerRegexp       string `json:"banner_regexp"`
+}
+
+// TCPProber 实现了 Prober 接口：拨号连接目标地址，可选地升级为 TLS 握手，
+// 并可选地匹配服务端返回的首行 banner
+type TCPProber struct{}
+
+// Probe 实现 Prober 接口
+func (p *TCPProber) Probe(item URLItem, timeout time.Duration) (ProbeResult, error) {
+	result := ProbeResult{Durations: make(map[string]float64)}
+
+	params := TCPParams{}
+	if item.TCP != nil {
+		params = *item.TCP
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+
+	connectStart := time.Now()
+	conn, err := dialer.Dial("tcp", item.URL)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+	result.Durations[phaseConnect] = time.Since(connectStart).Seconds()
+
+	if params.TLS {
+		tlsStart := time.Now()
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: params.InsecureSkipVerify})
+		if err := tlsConn.Handshake(); err != nil {
+			return result, fmt.Errorf("TLS 握手失败：%w", err)
+		}
+		result.Durations[phaseTLS] = time.Since(tlsStart).Seconds()
+
+		state := tlsConn.ConnectionState()
+		if len(state.PeerCertificates) > 0 {
+			result.HasSSL = true
+			result.SSLEarliestCertExpiry = earliestCertExpiry(state)
+		}
+		conn = tlsConn
+	}
+
+	result.Success = true
+
+	if params.BannerRegexp != "" {
+		re, err := regexp.Compile(params.BannerRegexp)
+		if err != nil {
+			return result, fmt.Errorf("编译 banner_regexp 失败：%w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		banner, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return result, fmt.Errorf("读取 banner 失败：%w", err)
+		}
+		result.Success = re.MatchString(banner)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsVersions 把配置中的字符串映射到 crypto/tls 的版本常量
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// buildServerTLSConfig 根据 TLSServerConfig 构建服务端使用的 *tls.Config，
+// 当 client_ca_file 非空时要求客户端证书（mTLS）
+func buildServerTLSConfig(cfg *TLSServerConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载 TLS 证书失败：%w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.MinVersion != "" {
+		version, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("未知的 min_version：%q", cfg.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	if cfg.MaxVersion != "" {
+		version, ok := tlsVersions[cfg.MaxVersion]
+		if !ok {
+			return nil, fmt.Errorf("未知的 max_version：%q", cfg.MaxVersion)
+		}
+		tlsConfig.MaxVersion = version
+	}
+
+	if cfg.ClientCAFile != "" {
+		caCert, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 client_ca_file 失败：%w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("client_ca_file 中没有可用的证书：%s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+// WebConfig 定义服务端的安全配置，风格上对齐 Prometheus exporter-toolkit 的
+// web.yml：TLS 证书、HTTP 服务器行为、bcrypt 哈希的 basic-auth 用户，
+// 以及可选的 IP allow-list
+type WebConfig struct {
+	BasicAuthUsers   map[string]string `json:"basic_auth_users" yaml:"basic_auth_users"`
+	IPRange          []string          `json:"ip_range" yaml:"ip_range"`
+	TLSServerConfig  *TLSServerConfig  `json:"tls_server_config" yaml:"tls_server_config"`
+	HTTPServerConfig *HTTPServerConfig `json:"http_server_config" yaml:"http_server_config"`
+}
+
+// TLSServerConfig 描述 /metrics 等端点使用的 TLS 证书与握手参数
+type TLSServerConfig struct {
+	CertFile     string `json:"cert_file" yaml:"cert_file"`
+	KeyFile      string `json:"key_file" yaml:"key_file"`
+	ClientCAFile string `json:"client_ca_file" yaml:"client_ca_file"`
+	MinVersion   string `json:"min_version" yaml:"min_version"`
+	MaxVersion   string `json:"max_version" yaml:"max_version"`
+}
+
+// HTTPServerConfig 描述 HTTP 服务器层面的行为开关
+type HTTPServerConfig struct {
+	HTTP2 bool `json:"http2" yaml:"http2"`
+}
+
+// loadWebConfig 读取 Web 安全配置文件，支持 JSON 或 YAML 格式
+func loadWebConfig(path string) (*WebConfig, error) {
+	var webConfig WebConfig
+	if err := decodeFile(path, &webConfig); err != nil {
+		return nil, err
+	}
+
+	return &webConfig, nil
+}
+
+// usesTLS 判断是否配置了 TLS 证书
+func (w *WebConfig) usesTLS() bool {
+	return w != nil && w.TLSServerConfig != nil && w.TLSServerConfig.CertFile != ""
+}